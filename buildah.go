@@ -0,0 +1,49 @@
+package main
+
+// BuildahEngine 使用 buildah 构建镜像、skopeo 推送，适用于没有 Docker daemon 的无根 CI Runner。
+// lastLocalTag 记录最近一次本地构建/标记的引用，push 时直接 copy 到完整镜像名，跳过 docker tag 语义
+type BuildahEngine struct {
+	lastLocalTag string
+}
+
+func (e *BuildahEngine) Build(file string, tag string, platforms []string) (err error) {
+	if len(platforms) == 0 {
+		if err = execCommand("buildah", "bud", "-f", file, "-t", tag, "."); err != nil {
+			return
+		}
+		e.lastLocalTag = tag
+		return
+	}
+	for _, platform := range platforms {
+		archTag := ArchTag(tag, platform)
+		if err = execCommand("buildah", "bud", "--platform", platform, "-f", file, "-t", archTag, "."); err != nil {
+			return
+		}
+		e.lastLocalTag = archTag
+	}
+	return
+}
+
+// Tag 不在本地重新打标，只记录下一次 Push 应当拷贝的本地引用
+func (e *BuildahEngine) Tag(src string, dst string) (err error) {
+	e.lastLocalTag = src
+	return nil
+}
+
+// Push 使用 skopeo 将本地存储中的镜像直接拷贝到完整镜像名，无需 daemon
+func (e *BuildahEngine) Push(tag string, dockerConfigDir string) (err error) {
+	return execCommand(
+		"skopeo", "copy",
+		"--authfile", authFilePath(dockerConfigDir),
+		"containers-storage:"+e.lastLocalTag,
+		"docker://"+tag,
+	)
+}
+
+func (e *BuildahEngine) RemoveImage(tag string) (err error) {
+	return execCommand("buildah", "rmi", tag)
+}
+
+func (e *BuildahEngine) Login(registry string, username string, password string) (err error) {
+	return execCommand("buildah", "login", "--username", username, "--password", password, registry)
+}
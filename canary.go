@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ExecuteCanaryRollout 基于线上工作负载 (Deployment 或 StatefulSet) 创建一个单副本的临时 canary 副本，
+// 使用新镜像进行灰度验证，仅当 canary 通过就绪探针后，调用方才应当对线上工作负载执行正式 patch
+func ExecuteCanaryRollout(kubeconfig string, namespace string, kind string, name string, container string, image string, timeout time.Duration) (err error) {
+	var buf []byte
+	if buf, err = execCommandOutput(
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"get", kind, name,
+		"-o", "json",
+	); err != nil {
+		return
+	}
+
+	var obj map[string]interface{}
+	if err = json.Unmarshal(buf, &obj); err != nil {
+		return
+	}
+
+	canaryName := name + "-canary"
+
+	delete(obj, "status")
+	metadata := asMap(obj, "metadata")
+	metadata["name"] = canaryName
+	delete(metadata, "resourceVersion")
+	delete(metadata, "uid")
+	delete(metadata, "creationTimestamp")
+
+	spec := asMap(obj, "spec")
+	spec["replicas"] = float64(1)
+
+	template := asMap(spec, "template")
+	templateLabels := asMap(asMap(template, "metadata"), "labels")
+	templateLabels["deployer.io/canary"] = canaryName
+
+	selector := asMap(spec, "selector")
+	matchLabels := asMap(selector, "matchLabels")
+	matchLabels["deployer.io/canary"] = canaryName
+
+	templateSpec := asMap(template, "spec")
+	containers, _ := templateSpec["containers"].([]interface{})
+
+	found := false
+	for _, c := range containers {
+		containerObj, _ := c.(map[string]interface{})
+		if containerObj["name"] == container {
+			containerObj["image"] = image
+			found = true
+		}
+	}
+	if !found {
+		err = fmt.Errorf("canary 验证失败: 工作负载 %s/%s 中未找到容器 %s", kind, name, container)
+		return
+	}
+
+	var canaryBuf []byte
+	if canaryBuf, err = json.Marshal(obj); err != nil {
+		return
+	}
+
+	defer func() {
+		log.Printf("清理 canary 工作负载: %s", canaryName)
+		_ = execCommand(
+			"kubectl",
+			"--kubeconfig="+kubeconfig,
+			"--namespace="+namespace,
+			"delete", kind, canaryName, "--ignore-not-found",
+		)
+	}()
+
+	log.Printf("创建 canary 工作负载: %s", canaryName)
+	if err = execCommandInput(
+		canaryBuf,
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"apply", "-f", "-",
+	); err != nil {
+		return
+	}
+
+	log.Printf("等待 canary 就绪: %s", canaryName)
+	return ExecuteKubectlRolloutStatus(kubeconfig, namespace, kind, canaryName, timeout)
+}
+
+// asMap 返回 obj[key] 对应的 map[string]interface{}，不存在或类型不符时创建一个空 map 并写回 obj，
+// 避免对 matchExpressions-only selector 或无 labels 的 template 做空 map 赋值时 panic
+func asMap(obj map[string]interface{}, key string) map[string]interface{} {
+	if m, ok := obj[key].(map[string]interface{}); ok {
+		return m
+	}
+	m := map[string]interface{}{}
+	obj[key] = m
+	return m
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DeployParams 汇总一次部署所需的上下文，供 patch/helm/kustomize 三种部署策略共用
+type DeployParams struct {
+	Workload       Workload
+	Preset         Preset
+	FileKubeconfig string
+	FullImageNames ImageNames
+	CPU            LimitOption
+	MEM            LimitOption
+	RolloutTimeout time.Duration
+	Canary         bool
+	Profile        Profile
+	Env            string
+}
+
+// Deploy 按 strategy 选择部署策略，默认使用 JSON 策略合并补丁
+func Deploy(strategy string, p DeployParams) error {
+	switch strategy {
+	case "helm":
+		return deployHelm(p)
+	case "kustomize":
+		return deployKustomize(p)
+	default:
+		return deployPatch(p)
+	}
+}
+
+// deployPatch 是默认策略：构建 JSON 策略合并补丁并调用 kubectl patch，随后校验上线结果
+func deployPatch(p DeployParams) (err error) {
+	var patch Patch
+	patch.Spec.Template.Metadata.Annotations.Timestamp = time.Now().Format(time.RFC3339)
+	for _, name := range p.Preset.ImagePullSecrets {
+		secret := PatchImagePullSecret{Name: strings.TrimSpace(name)}
+		patch.Spec.Template.Spec.ImagePullSecrets = append(patch.Spec.Template.Spec.ImagePullSecrets, secret)
+	}
+	if p.Workload.IsInit {
+		container := PatchInitContainer{
+			Image:           p.FullImageNames.Primary(),
+			Name:            p.Workload.Container,
+			ImagePullPolicy: "Always",
+		}
+		patch.Spec.Template.Spec.InitContainers = append(patch.Spec.Template.Spec.InitContainers, container)
+	} else {
+		container := PatchContainer{
+			Image:           p.FullImageNames.Primary(),
+			Name:            p.Workload.Container,
+			ImagePullPolicy: "Always",
+		}
+		container.Resources.Requests.CPU = p.Preset.RequestsCPU
+		container.Resources.Requests.Memory = p.Preset.RequestsMEM
+		container.Resources.Limits.CPU = p.Preset.LimitsCPU
+		container.Resources.Limits.Memory = p.Preset.LimitsMEM
+		if !p.CPU.IsZero() {
+			container.Resources.Requests.CPU = fmt.Sprintf("%dm", p.CPU.Min)
+			container.Resources.Limits.CPU = fmt.Sprintf("%dm", p.CPU.Max)
+		}
+		if !p.MEM.IsZero() {
+			container.Resources.Requests.Memory = fmt.Sprintf("%dMi", p.MEM.Min)
+			container.Resources.Limits.Memory = fmt.Sprintf("%dMi", p.MEM.Max)
+		}
+		patch.Spec.Template.Spec.Containers = append(patch.Spec.Template.Spec.Containers, container)
+	}
+
+	var buf []byte
+	if buf, err = json.Marshal(patch); err != nil {
+		return
+	}
+
+	if p.Canary && !p.Workload.IsInit {
+		log.Println("执行 canary 验证")
+		if err = ExecuteCanaryRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container, p.FullImageNames.Primary(), p.RolloutTimeout); err != nil {
+			return
+		}
+	}
+
+	var snapshot WorkloadSnapshot
+	if snapshot, err = ExecuteKubectlGetWorkloadSnapshot(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container); err != nil {
+		return
+	}
+
+	if err = ExecuteKubectlPatch(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Name, p.Workload.Type, string(buf)); err != nil {
+		return
+	}
+
+	log.Println("校验上线结果")
+	return WaitRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.RolloutTimeout, snapshot)
+}
+
+// deployHelm 使用 "helm upgrade --install" 部署，镜像与资源配额通过 --set 传入，
+// values 从 manifest 中约定的 values-<env>.yaml 合并。这里不使用 "--wait"，
+// 以免 helm 自身的就绪等待在失败时直接返回而跳过下面的 WaitRollout 回滚；
+// 部署前可选执行 canary 验证，部署后校验上线结果，失败时自动回滚，行为与 deployPatch 保持一致
+func deployHelm(p DeployParams) (err error) {
+	repo, tag, isDigest := splitImageRef(p.FullImageNames.Primary())
+
+	args := []string{
+		"upgrade", "--install", p.Profile.Helm.Release, p.Profile.Helm.Chart,
+		"--kubeconfig", p.FileKubeconfig,
+		"--namespace", p.Workload.Namespace,
+		"--set", "image.repository=" + repo,
+		"-f", "values-" + p.Env + ".yaml",
+	}
+	if isDigest {
+		args = append(args, "--set", "image.digest="+tag)
+	} else {
+		args = append(args, "--set", "image.tag="+tag)
+	}
+
+	if !p.CPU.IsZero() {
+		args = append(args,
+			"--set", fmt.Sprintf("resources.requests.cpu=%dm", p.CPU.Min),
+			"--set", fmt.Sprintf("resources.limits.cpu=%dm", p.CPU.Max),
+		)
+	}
+	if !p.MEM.IsZero() {
+		args = append(args,
+			"--set", fmt.Sprintf("resources.requests.memory=%dMi", p.MEM.Min),
+			"--set", fmt.Sprintf("resources.limits.memory=%dMi", p.MEM.Max),
+		)
+	}
+
+	if p.Canary && !p.Workload.IsInit {
+		log.Println("执行 canary 验证")
+		if err = ExecuteCanaryRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container, p.FullImageNames.Primary(), p.RolloutTimeout); err != nil {
+			return
+		}
+	}
+
+	var snapshot WorkloadSnapshot
+	if snapshot, err = ExecuteKubectlGetWorkloadSnapshot(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container); err != nil {
+		return
+	}
+
+	if err = execCommand("helm", args...); err != nil {
+		return
+	}
+
+	log.Println("校验上线结果")
+	return WaitRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.RolloutTimeout, snapshot)
+}
+
+// deployKustomize 在 overlay 目录中执行 "kustomize edit set image"，再用 "kubectl apply -k" 应用；
+// 部署前可选执行 canary 验证，部署后校验上线结果，失败时自动回滚，行为与 deployPatch 保持一致
+func deployKustomize(p DeployParams) (err error) {
+	repo, tag, isDigest := splitImageRef(p.FullImageNames.Primary())
+
+	imageArg := repo + ":" + tag
+	if isDigest {
+		imageArg = repo + "@" + tag
+	}
+	if err = execCommandIn(p.Profile.Kustomize.Overlay, "kustomize", "edit", "set", "image", imageArg); err != nil {
+		return
+	}
+
+	if !p.CPU.IsZero() || !p.MEM.IsZero() {
+		var patchFile string
+		if patchFile, err = writeKustomizeResourcesPatch(p); err != nil {
+			return
+		}
+		if err = execCommandIn(
+			p.Profile.Kustomize.Overlay,
+			"kustomize", "edit", "add", "patch",
+			"--path", patchFile,
+			"--kind", p.Workload.Type,
+			"--name", p.Workload.Name,
+		); err != nil {
+			return
+		}
+	}
+
+	if p.Canary && !p.Workload.IsInit {
+		log.Println("执行 canary 验证")
+		if err = ExecuteCanaryRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container, p.FullImageNames.Primary(), p.RolloutTimeout); err != nil {
+			return
+		}
+	}
+
+	var snapshot WorkloadSnapshot
+	if snapshot, err = ExecuteKubectlGetWorkloadSnapshot(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.Workload.Container); err != nil {
+		return
+	}
+
+	if err = execCommand("kubectl", "--kubeconfig="+p.FileKubeconfig, "apply", "-k", p.Profile.Kustomize.Overlay); err != nil {
+		return
+	}
+
+	log.Println("校验上线结果")
+	return WaitRollout(p.FileKubeconfig, p.Workload.Namespace, p.Workload.Type, p.Workload.Name, p.RolloutTimeout, snapshot)
+}
+
+// kustomizeResourcesPatch 是写入 overlay 目录、用于覆盖 CPU/MEM 配额的策略合并补丁；
+// 其中的容器同时带上主镜像和 imagePullPolicy: Always，与 deployPatch 写入的值一致
+type kustomizeResourcesPatch struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Template struct {
+			Spec struct {
+				Containers []PatchContainer `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+func writeKustomizeResourcesPatch(p DeployParams) (file string, err error) {
+	var patch kustomizeResourcesPatch
+	patch.APIVersion = "apps/v1"
+	patch.Kind = p.Workload.Type
+	patch.Metadata.Name = p.Workload.Name
+
+	container := PatchContainer{
+		Image:           p.FullImageNames.Primary(),
+		Name:            p.Workload.Container,
+		ImagePullPolicy: "Always",
+	}
+	if !p.CPU.IsZero() {
+		container.Resources.Requests.CPU = fmt.Sprintf("%dm", p.CPU.Min)
+		container.Resources.Limits.CPU = fmt.Sprintf("%dm", p.CPU.Max)
+	}
+	if !p.MEM.IsZero() {
+		container.Resources.Requests.Memory = fmt.Sprintf("%dMi", p.MEM.Min)
+		container.Resources.Limits.Memory = fmt.Sprintf("%dMi", p.MEM.Max)
+	}
+	patch.Spec.Template.Spec.Containers = append(patch.Spec.Template.Spec.Containers, container)
+
+	var buf []byte
+	if buf, err = json.Marshal(patch); err != nil {
+		return
+	}
+
+	// 返回相对 overlay 目录的文件名，供在 overlay 目录中执行的 "kustomize edit add patch --path" 使用
+	file = "deployer-kustomize-resources.json"
+	err = os.WriteFile(filepath.Join(p.Profile.Kustomize.Overlay, file), buf, 0644)
+	return
+}
+
+// splitImageRef 将镜像引用拆分为 repository 和 tag/digest 两部分，isDigest 标记该引用是否为 digest 锚定，
+// 调用方据此决定用 ":" 还是 "@" 重新拼接引用，避免把 digest 当作 tag 拼出 "repo:sha256:..." 这样的非法引用
+func splitImageRef(ref string) (repo string, tag string, isDigest bool) {
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		return ref[:idx], ref[idx+1:], true
+	}
+	if idx := strings.LastIndex(ref, ":"); idx >= 0 && !strings.Contains(ref[idx:], "/") {
+		return ref[:idx], ref[idx+1:], false
+	}
+	return ref, "latest", false
+}
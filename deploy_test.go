@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestSplitImageRef(t *testing.T) {
+	cases := []struct {
+		ref        string
+		wantRepo   string
+		wantTag    string
+		wantDigest bool
+	}{
+		{"registry.internal/org/app:v1", "registry.internal/org/app", "v1", false},
+		{"registry.internal/org/app", "registry.internal/org/app", "latest", false},
+		{"registry.internal/org/app@sha256:abcd", "registry.internal/org/app", "sha256:abcd", true},
+		{"localhost:5000/org/app:v1", "localhost:5000/org/app", "v1", false},
+	}
+
+	for _, c := range cases {
+		repo, tag, isDigest := splitImageRef(c.ref)
+		if repo != c.wantRepo || tag != c.wantTag || isDigest != c.wantDigest {
+			t.Errorf("splitImageRef(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				c.ref, repo, tag, isDigest, c.wantRepo, c.wantTag, c.wantDigest)
+		}
+	}
+}
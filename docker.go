@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DockerEngine 是默认的容器引擎实现，基于本地 Docker daemon
+type DockerEngine struct{}
+
+// Build 使用给定的 Dockerfile 构建镜像。当 platforms 非空时，为每个平台分别构建
+// 一个架构专属镜像（标签追加架构后缀），供推送阶段组装 manifest list 使用
+func (DockerEngine) Build(file string, tag string, platforms []string) (err error) {
+	if len(platforms) == 0 {
+		return execCommand("docker", "build", "-f", file, "-t", tag, ".")
+	}
+	for _, platform := range platforms {
+		if err = execCommand(
+			"docker", "buildx", "build",
+			"--platform", platform,
+			"--load",
+			"-f", file,
+			"-t", ArchTag(tag, platform),
+			".",
+		); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Tag 给本地镜像打上新标签
+func (DockerEngine) Tag(src string, dst string) (err error) {
+	return execCommand("docker", "tag", src, dst)
+}
+
+// Push 使用指定的 Docker 配置目录推送镜像
+func (DockerEngine) Push(tag string, dockerConfigDir string) (err error) {
+	return execCommand("docker", "--config", dockerConfigDir, "push", tag)
+}
+
+// RemoveImage 删除本地镜像
+func (DockerEngine) RemoveImage(tag string) (err error) {
+	return execCommand("docker", "rmi", tag)
+}
+
+// Login 登录到指定的镜像仓库
+func (DockerEngine) Login(registry string, username string, password string) (err error) {
+	return execCommand("docker", "login", "--username", username, "--password", password, registry)
+}
+
+// Execute 执行构建脚本文件
+func Execute(file string) (err error) {
+	return execCommand("bash", file)
+}
+
+// ExecuteDockerManifestPush 基于若干架构专属镜像组装并推送 manifest list，主标签指向该列表，
+// Kubernetes 节点拉取时会自动匹配到本机架构；目前仅 docker 引擎支持组装 manifest list
+func ExecuteDockerManifestPush(tag string, archTags []string, dockerConfigDir string) (err error) {
+	args := append([]string{"--config", dockerConfigDir, "manifest", "create", tag}, archTags...)
+	if err = execCommand("docker", args...); err != nil {
+		return
+	}
+	return execCommand("docker", "--config", dockerConfigDir, "manifest", "push", tag)
+}
+
+// ArchTag 根据平台 (如 linux/arm64) 为标签生成架构专属后缀
+func ArchTag(tag string, platform string) string {
+	return fmt.Sprintf("%s-%s", tag, strings.ReplaceAll(platform, "/", "-"))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ContainerEngine 抽象容器构建/标记/推送/清理能力，便于在没有 Docker daemon 的
+// rootless CI Runner 上切换到 podman/nerdctl/buildah 等替代实现
+type ContainerEngine interface {
+	Build(file string, tag string, platforms []string) error
+	Tag(src string, dst string) error
+	Push(tag string, dockerConfigDir string) error
+	RemoveImage(tag string) error
+	Login(registry string, username string, password string) error
+}
+
+// DetectContainerEngine 依次按传入的 name 参数、DEPLOYER_ENGINE 环境变量、
+// 探测 $PATH 的顺序选择容器引擎，都未命中时默认使用 docker
+func DetectContainerEngine(name string) (engine ContainerEngine, err error) {
+	if name == "" {
+		name = os.Getenv("DEPLOYER_ENGINE")
+	}
+	if name == "" {
+		for _, candidate := range []string{"docker", "podman", "nerdctl", "buildah"} {
+			if _, lookErr := exec.LookPath(candidate); lookErr == nil {
+				name = candidate
+				break
+			}
+		}
+	}
+
+	switch name {
+	case "", "docker":
+		return DockerEngine{}, nil
+	case "podman":
+		return PodmanEngine{}, nil
+	case "nerdctl":
+		return NerdctlEngine{}, nil
+	case "buildah":
+		return &BuildahEngine{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的容器引擎: %s", name)
+	}
+}
+
+// RequireManifestListSupport 在选择了多架构构建 (--platform) 时，校验当前容器引擎是否支持组装并推送
+// manifest list。目前只有 docker 引擎支持该能力 (ExecuteDockerManifestPush)，其它引擎应当尽早给出
+// 清晰错误，而不是在推送阶段深处才失败
+func RequireManifestListSupport(engine ContainerEngine, platforms []string) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+	if _, ok := engine.(DockerEngine); !ok {
+		return fmt.Errorf("--platform 多架构构建目前仅支持 --engine docker 组装 manifest list")
+	}
+	return nil
+}
+
+// RequireDigestResolutionSupport 在指定 --sign 时，校验当前容器引擎是否支持解析推送后的不可变 digest。
+// ExecuteDockerInspectDigest/ExecuteDockerManifestDigest 目前都固定调用 docker 命令，
+// 其它引擎应当尽早给出清晰错误，而不是在签名阶段深处才失败
+func RequireDigestResolutionSupport(engine ContainerEngine, sign bool) error {
+	if !sign {
+		return nil
+	}
+	if _, ok := engine.(DockerEngine); !ok {
+		return fmt.Errorf("--sign 目前仅支持 --engine docker 解析镜像 digest")
+	}
+	return nil
+}
+
+// authFilePath 返回 GenerateDockerconfig() 写入的 config.json 路径，
+// 该文件同时满足 docker --config、podman/buildah --authfile 的格式要求
+func authFilePath(dockerConfigDir string) string {
+	return filepath.Join(dockerConfigDir, "config.json")
+}
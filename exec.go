@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// execCommand 执行一个外部命令，将标准输出/标准错误转发到当前进程
+func execCommand(name string, args ...string) (err error) {
+	log.Printf("执行命令: %s %s", name, strings.Join(args, " "))
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// execCommandOutput 执行一个外部命令并返回其标准输出，标准错误仍转发到当前进程
+func execCommandOutput(name string, args ...string) (out []byte, err error) {
+	log.Printf("执行命令: %s %s", name, strings.Join(args, " "))
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// execCommandIn 在指定目录下执行一个外部命令，将标准输出/标准错误转发到当前进程
+func execCommandIn(dir string, name string, args ...string) (err error) {
+	log.Printf("执行命令 (%s): %s %s", dir, name, strings.Join(args, " "))
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// execCommandInput 执行一个外部命令，将 input 作为标准输入写入
+func execCommandInput(input []byte, name string, args ...string) (err error) {
+	log.Printf("执行命令: %s %s", name, strings.Join(args, " "))
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
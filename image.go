@@ -0,0 +1,24 @@
+package main
+
+import "strings"
+
+// ImageNames 是一组镜像名称，第一个元素始终是主标签
+type ImageNames []string
+
+// Primary 返回主标签
+func (n ImageNames) Primary() string {
+	return n[0]
+}
+
+// Derive 将一组镜像名称按照指定的 registry 重新生成完整镜像名
+func (n ImageNames) Derive(registry string) (out ImageNames) {
+	registry = strings.TrimSuffix(registry, "/")
+	for _, name := range n {
+		if registry == "" {
+			out = append(out, name)
+		} else {
+			out = append(out, registry+"/"+name)
+		}
+	}
+	return
+}
@@ -0,0 +1,15 @@
+package main
+
+// ExecuteKubectlPatch 使用指定的 kubeconfig，对某个工作负载执行策略合并补丁
+func ExecuteKubectlPatch(kubeconfig string, namespace string, name string, kind string, patch string) (err error) {
+	return execCommand(
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"patch",
+		kind,
+		name,
+		"--type=strategic",
+		"--patch="+patch,
+	)
+}
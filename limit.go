@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LimitOption 用于承载 "MIN:MAX" 格式的 --cpu / --mem 参数
+type LimitOption struct {
+	Min int
+	Max int
+}
+
+func (o *LimitOption) String() string {
+	if o.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", o.Min, o.Max)
+}
+
+func (o *LimitOption) Set(s string) error {
+	splits := strings.SplitN(s, ":", 2)
+	if len(splits) != 2 {
+		return fmt.Errorf("无效的配额格式: %s", s)
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(splits[0]))
+	if err != nil {
+		return fmt.Errorf("无效的配额格式: %s", s)
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(splits[1]))
+	if err != nil {
+		return fmt.Errorf("无效的配额格式: %s", s)
+	}
+	o.Min, o.Max = min, max
+	return nil
+}
+
+// IsZero 判断该配额是否未被设置
+func (o *LimitOption) IsZero() bool {
+	return o.Min == 0 && o.Max == 0
+}
@@ -1,10 +1,8 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
-	"fmt"
 	"github.com/guoyk93/tempfile"
 	"log"
 	"os"
@@ -30,13 +28,21 @@ func main() {
 	log.SetPrefix("[deployer] ")
 
 	var (
-		optManifest   string
-		optImage      string
-		optEnv        string
-		optWorkloads  WorkloadOptions
-		optCPU        LimitOption
-		optMEM        LimitOption
-		optSkipDeploy bool
+		optManifest         string
+		optImage            string
+		optEnv              string
+		optWorkloads        WorkloadOptions
+		optCPU              LimitOption
+		optMEM              LimitOption
+		optSkipDeploy       bool
+		optPlatforms        PlatformOptions
+		optRegistryOverride string
+		optRolloutTimeout   time.Duration
+		optCanary           bool
+		optEngine           string
+		optSign             bool
+		optSBOM             bool
+		optStrategy         string
 
 		imageNames     ImageNames
 		usedImageNames ImageNames
@@ -49,8 +55,21 @@ func main() {
 	flag.Var(&optWorkloads, "workload", "指定目标工作负载，格式为 \"CLUSTER/NAMESPACE/TYPE/NAME[/CONTAINER]\"")
 	flag.Var(&optCPU, "cpu", "指定 CPU 配额，格式为 \"MIN:MAX\"，单位为 m (千分之一核心)")
 	flag.Var(&optMEM, "mem", "指定 MEM 配额，格式为 \"MIN:MAX\"，单位为 Mi (兆字节)")
+	flag.Var(&optPlatforms, "platform", "指定构建平台，可重复指定，例如 \"linux/amd64\"，未指定时使用清单文件中的 platforms 配置")
+	flag.StringVar(&optRegistryOverride, "registry-override", "", "覆盖目标 registry，用于指向内部镜像仓库，未指定时读取 DEPLOYER_REGISTRY_OVERRIDE 环境变量")
+	flag.DurationVar(&optRolloutTimeout, "rollout-timeout", 5*time.Minute, "指定上线校验超时时间，超时后自动回滚")
+	flag.BoolVar(&optCanary, "canary", false, "先以单副本 canary 工作负载验证新镜像，通过后才执行正式部署")
+	flag.StringVar(&optEngine, "engine", "", "指定容器引擎 (docker/podman/nerdctl/buildah)，未指定时读取 DEPLOYER_ENGINE 环境变量或探测 $PATH")
+	flag.BoolVar(&optSign, "sign", false, "使用 cosign 对推送的镜像 digest 进行签名")
+	flag.BoolVar(&optSBOM, "sbom", false, "生成 SBOM 并通过 cosign attest 附加到镜像 digest，需要同时指定 --sign")
+	flag.StringVar(&optStrategy, "strategy", "", "指定部署策略 (patch/helm/kustomize)，未指定时使用清单文件中的 strategy 配置，默认为 patch")
 	flag.Parse()
 
+	var engine ContainerEngine
+	if engine, err = DetectContainerEngine(optEngine); err != nil {
+		return
+	}
+
 	// 从 JOB_NAME 获取 image 和 env 信息
 	if optImage == "" || optEnv == "" {
 		if jobNameSplits := strings.Split(os.Getenv("JOB_NAME"), "."); len(jobNameSplits) == 2 {
@@ -86,6 +105,31 @@ func main() {
 	log.Printf("写入构建文件: %s", fileBuild)
 	log.Printf("写入打包文件: %s", filePackage)
 
+	if len(optPlatforms) == 0 {
+		optPlatforms = m.Profile(optEnv).Platforms
+	}
+	if len(optPlatforms) > 0 {
+		log.Printf("使用多架构平台: %s", strings.Join(optPlatforms, ", "))
+	}
+	if err = RequireManifestListSupport(engine, optPlatforms); err != nil {
+		return
+	}
+	if err = RequireDigestResolutionSupport(engine, optSign); err != nil {
+		return
+	}
+	if optSBOM && !optSign {
+		err = errors.New("--sbom 需要同时指定 --sign")
+		return
+	}
+
+	if optStrategy == "" {
+		optStrategy = m.Profile(optEnv).Strategy
+	}
+	if optStrategy == "" {
+		optStrategy = "patch"
+	}
+	log.Printf("使用部署策略: %s", optStrategy)
+
 	log.Println("执行构建流程")
 	if err = Execute(fileBuild); err != nil {
 		return
@@ -93,17 +137,23 @@ func main() {
 	log.Println("构建完成")
 
 	log.Println("执行打包流程")
-	if err = ExecuteDockerBuild(filePackage, imageNames.Primary()); err != nil {
+	if err = engine.Build(filePackage, imageNames.Primary(), optPlatforms); err != nil {
 		return
 	}
 
 	log.Printf("打包完成: %s", imageNames.Primary())
-	usedImageNames = append(usedImageNames, imageNames.Primary())
+	if len(optPlatforms) == 0 {
+		usedImageNames = append(usedImageNames, imageNames.Primary())
+	} else {
+		for _, platform := range optPlatforms {
+			usedImageNames = append(usedImageNames, ArchTag(imageNames.Primary(), platform))
+		}
+	}
 
 	defer func() {
 		log.Printf("清理镜像")
 		for _, imageName := range usedImageNames {
-			_ = ExecuteDockerRemoveImage(imageName)
+			_ = engine.RemoveImage(imageName)
 		}
 	}()
 
@@ -118,6 +168,18 @@ func main() {
 
 		fullImageNames := imageNames.Derive(s.Registry)
 
+		ReloadRegistryConfig(RegistryConfig{
+			DefaultRegistry: s.DefaultRegistry,
+			Mirrors:         s.Mirrors,
+			Override:        optRegistryOverride,
+		})
+		for i, fullImageName := range fullImageNames {
+			if rewritten := RewriteImage(fullImageName); rewritten != fullImageName {
+				log.Printf("镜像仓库重写: %s -> %s", fullImageName, rewritten)
+				fullImageNames[i] = rewritten
+			}
+		}
+
 		var dcDir, dcFile string
 		if dcDir, dcFile, err = tempfile.WriteDirFile(
 			s.GenerateDockerconfig(),
@@ -129,17 +191,79 @@ func main() {
 		}
 		log.Printf("生成 Docker 配置文件: %s", dcFile)
 
-		for _, fullImageName := range fullImageNames {
+		for i, fullImageName := range fullImageNames {
 			log.Printf("推送镜像: %s", fullImageName)
 
-			if err = ExecuteDockerTag(imageNames.Primary(), fullImageName); err != nil {
-				return
+			var pushedRef string
+
+			if len(optPlatforms) == 0 {
+				if err = engine.Tag(imageNames.Primary(), fullImageName); err != nil {
+					return
+				}
+
+				usedImageNames = append(usedImageNames, fullImageName)
+
+				if err = engine.Push(fullImageName, dcDir); err != nil {
+					return
+				}
+
+				pushedRef = fullImageName
+				if optSign {
+					if pushedRef, err = ExecuteDockerInspectDigest(fullImageName); err != nil {
+						return
+					}
+				}
+			} else {
+				// 多架构场景: 逐个推送架构专属镜像，再组装推送 manifest list，主标签指向该列表
+				var archImageNames []string
+				for _, platform := range optPlatforms {
+					archImageName := ArchTag(fullImageName, platform)
+
+					if err = engine.Tag(ArchTag(imageNames.Primary(), platform), archImageName); err != nil {
+						return
+					}
+
+					usedImageNames = append(usedImageNames, archImageName)
+					archImageNames = append(archImageNames, archImageName)
+
+					if err = engine.Push(archImageName, dcDir); err != nil {
+						return
+					}
+				}
+
+				log.Printf("组装并推送 manifest list: %s", fullImageName)
+				if err = ExecuteDockerManifestPush(fullImageName, archImageNames, dcDir); err != nil {
+					return
+				}
+
+				pushedRef = fullImageName
+				if optSign {
+					if pushedRef, err = ExecuteDockerManifestDigest(fullImageName); err != nil {
+						return
+					}
+				}
 			}
 
-			usedImageNames = append(usedImageNames, fullImageName)
+			if optSign {
+				log.Printf("签名镜像: %s", pushedRef)
+				if err = ExecuteCosignSign(pushedRef, s.Signing); err != nil {
+					return
+				}
+
+				if optSBOM {
+					var sbomFile string
+					if sbomFile, err = ExecuteSyftSBOM(pushedRef); err != nil {
+						return
+					}
+					log.Printf("生成 SBOM: %s", sbomFile)
 
-			if err = ExecuteDockerPush(fullImageName, dcDir); err != nil {
-				return
+					if err = ExecuteCosignAttest(pushedRef, sbomFile, s.Signing); err != nil {
+						return
+					}
+				}
+
+				// 将 patch 锚定到已签名的 digest，而不是可变的 tag
+				fullImageNames[i] = pushedRef
 			}
 		}
 
@@ -153,48 +277,19 @@ func main() {
 		}
 		log.Printf("生成 Kubeconfig 文件: %s", fileKubeconfig)
 
-		// 构建 Patch
-		var p Patch
-		p.Spec.Template.Metadata.Annotations.Timestamp = time.Now().Format(time.RFC3339)
-		for _, name := range s.ImagePullSecrets {
-			secret := PatchImagePullSecret{Name: strings.TrimSpace(name)}
-			p.Spec.Template.Spec.ImagePullSecrets = append(p.Spec.Template.Spec.ImagePullSecrets, secret)
-		}
-		if workload.IsInit {
-			container := PatchInitContainer{
-				Image:           fullImageNames.Primary(),
-				Name:            workload.Container,
-				ImagePullPolicy: "Always",
-			}
-			p.Spec.Template.Spec.InitContainers = append(p.Spec.Template.Spec.InitContainers, container)
-		} else {
-			container := PatchContainer{
-				Image:           fullImageNames.Primary(),
-				Name:            workload.Container,
-				ImagePullPolicy: "Always",
-			}
-			container.Resources.Requests.CPU = s.RequestsCPU
-			container.Resources.Requests.Memory = s.RequestsMEM
-			container.Resources.Limits.CPU = s.LimitsCPU
-			container.Resources.Limits.Memory = s.LimitsMEM
-			if !optCPU.IsZero() {
-				container.Resources.Requests.CPU = fmt.Sprintf("%dm", optCPU.Min)
-				container.Resources.Limits.CPU = fmt.Sprintf("%dm", optCPU.Max)
-			}
-			if !optMEM.IsZero() {
-				container.Resources.Requests.Memory = fmt.Sprintf("%dMi", optMEM.Min)
-				container.Resources.Limits.Memory = fmt.Sprintf("%dMi", optMEM.Max)
-			}
-			p.Spec.Template.Spec.Containers = append(p.Spec.Template.Spec.Containers, container)
-		}
-
-		var buf []byte
-		if buf, err = json.Marshal(p); err != nil {
-			return
-		}
-
-		if err = ExecuteKubectlPatch(fileKubeconfig, workload.Namespace, workload.Namespace, workload.Type, string(buf)); err != nil {
+		if err = Deploy(optStrategy, DeployParams{
+			Workload:       workload,
+			Preset:         s,
+			FileKubeconfig: fileKubeconfig,
+			FullImageNames: fullImageNames,
+			CPU:            optCPU,
+			MEM:            optMEM,
+			RolloutTimeout: optRolloutTimeout,
+			Canary:         optCanary,
+			Profile:        m.Profile(optEnv),
+			Env:            optEnv,
+		}); err != nil {
 			return
 		}
 	}
-}
\ No newline at end of file
+}
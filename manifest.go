@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/guoyk93/tempfile"
+	"gopkg.in/yaml.v2"
+)
+
+// Profile 是 deployer.yml 中针对某个环境的配置
+type Profile struct {
+	Build     string   `yaml:"build"`
+	Package   string   `yaml:"package"`
+	Platforms []string `yaml:"platforms"`
+
+	Strategy  string          `yaml:"strategy"` // patch(默认)|helm|kustomize
+	Helm      HelmConfig      `yaml:"helm"`
+	Kustomize KustomizeConfig `yaml:"kustomize"`
+}
+
+// HelmConfig 是 strategy: helm 模式下使用的 release/chart 配置
+type HelmConfig struct {
+	Release string `yaml:"release"`
+	Chart   string `yaml:"chart"`
+}
+
+// KustomizeConfig 是 strategy: kustomize 模式下使用的 overlay 目录配置
+type KustomizeConfig struct {
+	Overlay string `yaml:"overlay"`
+}
+
+// GenerateFiles 将 Build/Package 脚本写入临时文件，返回文件路径
+func (p Profile) GenerateFiles() (fileBuild string, filePackage string, err error) {
+	if fileBuild, err = tempfile.WriteFile([]byte(p.Build), "deployer-build", ".sh", false); err != nil {
+		return
+	}
+	if filePackage, err = tempfile.WriteFile([]byte(p.Package), "deployer-package", "", false); err != nil {
+		return
+	}
+	return
+}
+
+// Manifest 对应 deployer.yml 的根节点
+type Manifest struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile 根据环境名返回对应的 Profile
+func (m Manifest) Profile(env string) Profile {
+	return m.Profiles[env]
+}
+
+// LoadManifestFile 从指定路径加载 Manifest
+func LoadManifestFile(filename string) (m Manifest, err error) {
+	var buf []byte
+	if buf, err = os.ReadFile(filename); err != nil {
+		err = fmt.Errorf("加载清单文件失败: %w", err)
+		return
+	}
+	if err = yaml.Unmarshal(buf, &m); err != nil {
+		err = fmt.Errorf("解析清单文件失败: %w", err)
+		return
+	}
+	return
+}
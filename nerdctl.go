@@ -0,0 +1,32 @@
+package main
+
+// NerdctlEngine 适配基于 containerd 的 nerdctl，命令行语义与 docker 基本兼容
+type NerdctlEngine struct{}
+
+func (NerdctlEngine) Build(file string, tag string, platforms []string) (err error) {
+	if len(platforms) == 0 {
+		return execCommand("nerdctl", "build", "-f", file, "-t", tag, ".")
+	}
+	for _, platform := range platforms {
+		if err = execCommand("nerdctl", "build", "--platform", platform, "-f", file, "-t", ArchTag(tag, platform), "."); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (NerdctlEngine) Tag(src string, dst string) (err error) {
+	return execCommand("nerdctl", "tag", src, dst)
+}
+
+func (NerdctlEngine) Push(tag string, dockerConfigDir string) (err error) {
+	return execCommand("nerdctl", "--config", dockerConfigDir, "push", tag)
+}
+
+func (NerdctlEngine) RemoveImage(tag string) (err error) {
+	return execCommand("nerdctl", "rmi", tag)
+}
+
+func (NerdctlEngine) Login(registry string, username string, password string) (err error) {
+	return execCommand("nerdctl", "login", "--username", username, "--password", password, registry)
+}
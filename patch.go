@@ -0,0 +1,48 @@
+package main
+
+// Patch 是用于 kubectl patch 的 JSON 策略合并补丁
+type Patch struct {
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Annotations struct {
+					Timestamp string `json:"deployer.io/timestamp,omitempty"`
+				} `json:"annotations"`
+			} `json:"metadata"`
+			Spec struct {
+				ImagePullSecrets []PatchImagePullSecret `json:"imagePullSecrets,omitempty"`
+				InitContainers   []PatchInitContainer   `json:"initContainers,omitempty"`
+				Containers       []PatchContainer       `json:"containers,omitempty"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// PatchImagePullSecret 对应 Pod 的 imagePullSecrets 条目
+type PatchImagePullSecret struct {
+	Name string `json:"name"`
+}
+
+// PatchInitContainer 对应待合并的 initContainer
+type PatchInitContainer struct {
+	Image           string `json:"image"`
+	Name            string `json:"name"`
+	ImagePullPolicy string `json:"imagePullPolicy"`
+}
+
+// PatchContainer 对应待合并的 container
+type PatchContainer struct {
+	Image           string `json:"image"`
+	Name            string `json:"name"`
+	ImagePullPolicy string `json:"imagePullPolicy"`
+	Resources       struct {
+		Requests struct {
+			CPU    string `json:"cpu,omitempty"`
+			Memory string `json:"memory,omitempty"`
+		} `json:"requests"`
+		Limits struct {
+			CPU    string `json:"cpu,omitempty"`
+			Memory string `json:"memory,omitempty"`
+		} `json:"limits"`
+	} `json:"resources"`
+}
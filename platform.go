@@ -0,0 +1,15 @@
+package main
+
+import "strings"
+
+// PlatformOptions 实现 flag.Value，支持重复传入 --platform，例如 "linux/amd64"
+type PlatformOptions []string
+
+func (o *PlatformOptions) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *PlatformOptions) Set(s string) error {
+	*o = append(*o, strings.TrimSpace(s))
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+// PodmanEngine 适配 rootless 场景下的 podman
+type PodmanEngine struct{}
+
+func (PodmanEngine) Build(file string, tag string, platforms []string) (err error) {
+	if len(platforms) == 0 {
+		return execCommand("podman", "build", "-f", file, "-t", tag, ".")
+	}
+	for _, platform := range platforms {
+		if err = execCommand("podman", "build", "--platform", platform, "-f", file, "-t", ArchTag(tag, platform), "."); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (PodmanEngine) Tag(src string, dst string) (err error) {
+	return execCommand("podman", "tag", src, dst)
+}
+
+// Push 使用 --authfile 而非 docker 风格的 --config 目录进行推送
+func (PodmanEngine) Push(tag string, dockerConfigDir string) (err error) {
+	return execCommand("podman", "push", "--authfile", authFilePath(dockerConfigDir), tag)
+}
+
+func (PodmanEngine) RemoveImage(tag string) (err error) {
+	return execCommand("podman", "rmi", tag)
+}
+
+func (PodmanEngine) Login(registry string, username string, password string) (err error) {
+	return execCommand("podman", "login", "--username", username, "--password", password, registry)
+}
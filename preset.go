@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Preset 是集群相关的预设信息，由运维统一维护，存放于 presets/<cluster>.yml
+type Preset struct {
+	Registry         string   `yaml:"registry"`
+	ImagePullSecrets []string `yaml:"imagePullSecrets"`
+
+	DefaultRegistry string           `yaml:"defaultRegistry"`
+	Mirrors         []RegistryMirror `yaml:"mirrors"`
+
+	RequestsCPU string `yaml:"requestsCPU"`
+	RequestsMEM string `yaml:"requestsMEM"`
+	LimitsCPU   string `yaml:"limitsCPU"`
+	LimitsMEM   string `yaml:"limitsMEM"`
+
+	DockerUsername string `yaml:"dockerUsername"`
+	DockerPassword string `yaml:"dockerPassword"`
+
+	Kubeconfig string `yaml:"kubeconfig"`
+
+	Signing Signing `yaml:"signing"`
+}
+
+// Signing 描述镜像签名/SBOM 证明所需的 cosign 配置
+type Signing struct {
+	Mode     string `yaml:"mode"` // keyless|keyfile
+	Key      string `yaml:"key"`
+	Identity string `yaml:"identity"`
+	Issuer   string `yaml:"issuer"`
+	Fulcio   string `yaml:"fulcio"`
+	Rekor    string `yaml:"rekor"`
+}
+
+// GenerateDockerconfig 生成供 docker push 使用的 config.json 内容。除了 s.Registry 本身，
+// 还为当前生效的镜像仓库重写目标 (registryConfig，需先调用 ReloadRegistryConfig) 写入同样的凭证，
+// 否则 RewriteImage 改写后的推送目标会因缺少认证信息而失败
+func (s Preset) GenerateDockerconfig() []byte {
+	auth := base64.StdEncoding.EncodeToString([]byte(s.DockerUsername + ":" + s.DockerPassword))
+
+	auths := map[string]map[string]string{}
+	addAuth := func(registry string) {
+		if registry == "" {
+			return
+		}
+		auths[registry] = map[string]string{"auth": auth}
+	}
+
+	addAuth(s.Registry)
+	addAuth(registryConfig.DefaultRegistry)
+	addAuth(registryConfig.Override)
+	for _, mirror := range registryConfig.Mirrors {
+		addAuth(mirror.To)
+	}
+
+	buf, _ := json.Marshal(struct {
+		Auths map[string]map[string]string `json:"auths"`
+	}{Auths: auths})
+	return buf
+}
+
+// GenerateKubeconfig 生成供 kubectl 使用的 kubeconfig 内容
+func (s Preset) GenerateKubeconfig() []byte {
+	return []byte(s.Kubeconfig)
+}
+
+// LoadPreset 根据集群名加载 Preset
+func LoadPreset(cluster string) (s Preset, err error) {
+	var buf []byte
+	if buf, err = os.ReadFile("presets/" + cluster + ".yml"); err != nil {
+		err = fmt.Errorf("加载预设文件失败: %w", err)
+		return
+	}
+	if err = yaml.Unmarshal(buf, &s); err != nil {
+		err = fmt.Errorf("解析预设文件失败: %w", err)
+		return
+	}
+	return
+}
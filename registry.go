@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// RegistryMirror 描述一条镜像仓库替换规则，按声明顺序匹配第一条 From 与镜像 registry 部分完全相等的规则
+type RegistryMirror struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// RegistryConfig 是镜像仓库重写相关的配置
+type RegistryConfig struct {
+	DefaultRegistry string
+	Mirrors         []RegistryMirror
+	Override        string
+}
+
+var registryConfig RegistryConfig
+
+// ReloadRegistryConfig 重新载入镜像仓库重写配置，Override 优先级最高，
+// 未显式传入时回退到 DEPLOYER_REGISTRY_OVERRIDE 环境变量
+func ReloadRegistryConfig(cfg RegistryConfig) {
+	if cfg.Override == "" {
+		cfg.Override = os.Getenv("DEPLOYER_REGISTRY_OVERRIDE")
+	}
+	registryConfig = cfg
+}
+
+// RewriteImage 解析 "registry/repo:tag" 形式的镜像引用（缺失 registry 时补全为 docker.io，
+// 缺失 tag 时补全为 latest，保留 digest 引用），并按当前 RegistryConfig 重写其 registry 部分：
+// 优先匹配 Mirrors 中第一条与 registry 完全相等的规则，否则使用 Override，再否则使用 DefaultRegistry。
+// 未配置任何 Mirrors/Override/DefaultRegistry 时原样返回 ref，不对已有部署产生影响
+func RewriteImage(ref string) string {
+	if !registryConfig.configured() {
+		return ref
+	}
+
+	registry, rest := splitImageRegistry(ref)
+
+	rewritten := registry
+	matched := false
+	for _, mirror := range registryConfig.Mirrors {
+		if mirror.From != "" && mirror.From == registry {
+			rewritten = mirror.To
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		if registryConfig.Override != "" {
+			rewritten = registryConfig.Override
+		} else if registryConfig.DefaultRegistry != "" {
+			rewritten = registryConfig.DefaultRegistry
+		}
+	}
+
+	return rewritten + "/" + rest
+}
+
+// configured 判断当前是否配置了任何镜像仓库重写规则
+func (c RegistryConfig) configured() bool {
+	return len(c.Mirrors) > 0 || c.Override != "" || c.DefaultRegistry != ""
+}
+
+// splitImageRegistry 从镜像引用中拆分出 registry 部分和剩余的 repo[:tag|@digest] 部分，
+// 缺失 registry 时补全为 docker.io，缺失 tag 时补全为 latest
+func splitImageRegistry(ref string) (registry string, rest string) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry, rest = parts[0], parts[1]
+	} else {
+		registry, rest = "docker.io", ref
+	}
+
+	if !strings.Contains(rest, "@") {
+		repo, tag := rest, "latest"
+		if idx := strings.LastIndex(rest, ":"); idx >= 0 && !strings.Contains(rest[idx:], "/") {
+			repo, tag = rest[:idx], rest[idx+1:]
+		}
+		rest = repo + ":" + tag
+	}
+
+	return
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestRewriteImage(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  RegistryConfig
+		ref  string
+		want string
+	}{
+		{
+			name: "未配置时原样返回",
+			cfg:  RegistryConfig{},
+			ref:  "quay.io/org/app:v1",
+			want: "quay.io/org/app:v1",
+		},
+		{
+			name: "镜像规则精确匹配才重写",
+			cfg:  RegistryConfig{Mirrors: []RegistryMirror{{From: "quay.io", To: "mirror.internal"}}},
+			ref:  "quay.io/org/app:v1",
+			want: "mirror.internal/org/app:v1",
+		},
+		{
+			name: "前缀相似但非完全匹配的 registry 不应被误判为镜像命中",
+			cfg:  RegistryConfig{Mirrors: []RegistryMirror{{From: "quay.io", To: "mirror.internal"}}},
+			ref:  "quay.iofake.com/org/app:v1",
+			want: "quay.iofake.com/org/app:v1",
+		},
+		{
+			name: "未命中任何镜像规则时回退到 Override",
+			cfg:  RegistryConfig{Mirrors: []RegistryMirror{{From: "quay.io", To: "mirror.internal"}}, Override: "override.internal"},
+			ref:  "docker.io/org/app:v1",
+			want: "override.internal/org/app:v1",
+		},
+		{
+			name: "未命中任何镜像规则也没有 Override 时回退到 DefaultRegistry",
+			cfg:  RegistryConfig{DefaultRegistry: "default.internal"},
+			ref:  "org/app:v1",
+			want: "default.internal/org/app:v1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ReloadRegistryConfig(c.cfg)
+			if got := RewriteImage(c.ref); got != c.want {
+				t.Errorf("RewriteImage(%q) = %q, want %q", c.ref, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSplitImageRegistry(t *testing.T) {
+	cases := []struct {
+		ref          string
+		wantRegistry string
+		wantRest     string
+	}{
+		{"quay.io/org/app:v1", "quay.io", "org/app:v1"},
+		{"org/app", "docker.io", "org/app:latest"},
+		{"org/app@sha256:abcd", "docker.io", "org/app@sha256:abcd"},
+		{"localhost:5000/org/app:v1", "localhost:5000", "org/app:v1"},
+	}
+
+	for _, c := range cases {
+		registry, rest := splitImageRegistry(c.ref)
+		if registry != c.wantRegistry || rest != c.wantRest {
+			t.Errorf("splitImageRegistry(%q) = (%q, %q), want (%q, %q)", c.ref, registry, rest, c.wantRegistry, c.wantRest)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// WorkloadSnapshot 记录 patch 之前的工作负载状态，用于回滚时的信息展示与核对
+type WorkloadSnapshot struct {
+	Image           string
+	PodTemplateHash string
+}
+
+// ExecuteKubectlGetWorkloadSnapshot 在 patch 之前读取指定容器的镜像，以及工作负载自身 metadata.labels
+// 中的 pod-template-hash（没有该 label 时为空）。记录的内容只用于回滚时的日志展示；
+// 回滚由 ExecuteKubectlRolloutUndo 回到上一个 revision 完成，不以这里记录的镜像或 hash 锚定回滚目标
+func ExecuteKubectlGetWorkloadSnapshot(kubeconfig string, namespace string, kind string, name string, container string) (snapshot WorkloadSnapshot, err error) {
+	var buf []byte
+	if buf, err = execCommandOutput(
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"get",
+		kind,
+		name,
+		"-o", "json",
+	); err != nil {
+		return
+	}
+
+	var obj struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Name  string `json:"name"`
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err = json.Unmarshal(buf, &obj); err != nil {
+		return
+	}
+
+	snapshot.PodTemplateHash = obj.Metadata.Labels["pod-template-hash"]
+	for _, c := range obj.Spec.Template.Spec.Containers {
+		if c.Name == container {
+			snapshot.Image = c.Image
+		}
+	}
+	return
+}
+
+// ExecuteKubectlRolloutStatus 等待工作负载上线完成，超过 timeout 视为失败
+func ExecuteKubectlRolloutStatus(kubeconfig string, namespace string, kind string, name string, timeout time.Duration) (err error) {
+	return execCommand(
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"rollout", "status",
+		kind+"/"+name,
+		fmt.Sprintf("--timeout=%s", timeout),
+	)
+}
+
+// ExecuteKubectlRolloutUndo 回滚工作负载到 patch 之前的 revision
+func ExecuteKubectlRolloutUndo(kubeconfig string, namespace string, kind string, name string) (err error) {
+	return execCommand(
+		"kubectl",
+		"--kubeconfig="+kubeconfig,
+		"--namespace="+namespace,
+		"rollout", "undo",
+		kind+"/"+name,
+	)
+}
+
+// WaitRollout 在 kubectl patch 之后校验上线结果，超时或失败时自动回滚并返回错误，
+// 使 CI 任务能感知失败，而不是静默停留在新旧版本之间
+func WaitRollout(kubeconfig string, namespace string, kind string, name string, timeout time.Duration, snapshot WorkloadSnapshot) (err error) {
+	if err = ExecuteKubectlRolloutStatus(kubeconfig, namespace, kind, name, timeout); err != nil {
+		log.Printf("上线校验失败，回滚到 patch 前镜像: %s", snapshot.Image)
+		if undoErr := ExecuteKubectlRolloutUndo(kubeconfig, namespace, kind, name); undoErr != nil {
+			return fmt.Errorf("上线校验失败(%s)，且自动回滚失败: %w", err.Error(), undoErr)
+		}
+		return fmt.Errorf("上线校验失败，已自动回滚: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/guoyk93/tempfile"
+)
+
+// repoOf 返回镜像引用去掉 tag 之后的仓库部分，例如 "a/b:v1" -> "a/b"
+func repoOf(ref string) string {
+	if idx := strings.LastIndex(ref, ":"); idx >= 0 && !strings.Contains(ref[idx:], "/") {
+		return ref[:idx]
+	}
+	return ref
+}
+
+// ExecuteDockerInspectDigest 解析 docker push 之后的不可变 digest，而非可变的 tag，
+// 用于让签名和 kubectl patch 都锚定到确定的内容
+func ExecuteDockerInspectDigest(tag string) (ref string, err error) {
+	var buf []byte
+	if buf, err = execCommandOutput("docker", "inspect", "--format", "{{json .RepoDigests}}", tag); err != nil {
+		return
+	}
+
+	var digests []string
+	if err = json.Unmarshal(buf, &digests); err != nil {
+		return
+	}
+
+	repo := repoOf(tag)
+	for _, d := range digests {
+		if strings.HasPrefix(d, repo+"@") {
+			ref = d
+			return
+		}
+	}
+	if len(digests) > 0 {
+		ref = digests[len(digests)-1]
+	}
+	return
+}
+
+// ExecuteDockerManifestDigest 解析已推送 manifest list 的 digest，用于多架构场景下的签名与 patch
+func ExecuteDockerManifestDigest(tag string) (ref string, err error) {
+	var buf []byte
+	if buf, err = execCommandOutput("docker", "buildx", "imagetools", "inspect", tag, "--format", "{{json .Manifest.Digest}}"); err != nil {
+		return
+	}
+
+	var digest string
+	if err = json.Unmarshal(buf, &digest); err != nil {
+		return
+	}
+
+	ref = repoOf(tag) + "@" + digest
+	return
+}
+
+// ExecuteCosignSign 对指定的镜像 digest 进行签名，按 Signing.Mode 选择 keyless OIDC 或 key 文件
+func ExecuteCosignSign(ref string, signing Signing) (err error) {
+	args := []string{"sign", "--yes"}
+	args = append(args, cosignAuthArgs(signing)...)
+	args = append(args, ref)
+	return execCommand("cosign", args...)
+}
+
+// ExecuteSyftSBOM 使用 syft 为指定镜像生成 CycloneDX 格式的 SBOM 文件
+func ExecuteSyftSBOM(ref string) (file string, err error) {
+	if file, err = tempfile.WriteFile(nil, "deployer-sbom", ".json", false); err != nil {
+		return
+	}
+	err = execCommand("syft", ref, "-o", "cyclonedx-json="+file)
+	return
+}
+
+// ExecuteCosignAttest 使用 cosign 将 SBOM 作为 attestation 附加到指定的镜像 digest 上
+func ExecuteCosignAttest(ref string, sbomFile string, signing Signing) (err error) {
+	args := []string{"attest", "--yes", "--type", "cyclonedx", "--predicate", sbomFile}
+	args = append(args, cosignAuthArgs(signing)...)
+	args = append(args, ref)
+	return execCommand("cosign", args...)
+}
+
+// cosignAuthArgs 根据 Signing 配置拼装 cosign 的鉴权相关参数
+func cosignAuthArgs(signing Signing) (args []string) {
+	if signing.Mode == "keyfile" {
+		args = append(args, "--key", signing.Key)
+		return
+	}
+	if signing.Issuer != "" {
+		args = append(args, "--oidc-issuer", signing.Issuer)
+	}
+	if signing.Identity != "" {
+		args = append(args, "--oidc-client-id", signing.Identity)
+	}
+	if signing.Fulcio != "" {
+		args = append(args, "--fulcio-url", signing.Fulcio)
+	}
+	if signing.Rekor != "" {
+		args = append(args, "--rekor-url", signing.Rekor)
+	}
+	return
+}
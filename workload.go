@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Workload 描述一个部署目标，格式为 "CLUSTER/NAMESPACE/TYPE/NAME[/CONTAINER]"
+type Workload struct {
+	Cluster   string
+	Namespace string
+	Type      string
+	Name      string
+	Container string
+	IsInit    bool
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", w.Cluster, w.Namespace, w.Type, w.Name, w.Container)
+}
+
+// WorkloadOptions 实现 flag.Value，支持重复传入 --workload
+type WorkloadOptions []Workload
+
+func (o *WorkloadOptions) String() string {
+	var items []string
+	for _, w := range *o {
+		items = append(items, w.String())
+	}
+	return strings.Join(items, ",")
+}
+
+func (o *WorkloadOptions) Set(s string) error {
+	splits := strings.Split(s, "/")
+	if len(splits) != 4 && len(splits) != 5 {
+		return fmt.Errorf("无效的 --workload 格式: %s", s)
+	}
+	w := Workload{
+		Cluster:   splits[0],
+		Namespace: splits[1],
+		Type:      splits[2],
+		Name:      splits[3],
+		Container: splits[3],
+	}
+	if len(splits) == 5 {
+		w.Container = splits[4]
+	}
+	if strings.EqualFold(w.Type, "initcontainer") {
+		w.IsInit = true
+	}
+	*o = append(*o, w)
+	return nil
+}